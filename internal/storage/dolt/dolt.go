@@ -0,0 +1,201 @@
+package dolt
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	_ "github.com/dolthub/driver"
+)
+
+// Config describes how to open a Dolt-backed store.
+type Config struct {
+	// Path is the directory holding the embedded Dolt databases.
+	Path string
+	// Database is the Dolt database name to select within Path.
+	Database string
+
+	CommitterName  string
+	CommitterEmail string
+
+	// IdleClose is how long a shared handle is kept open after its last
+	// reference is released before the underlying connection is torn down.
+	// Zero uses defaultIdleClose.
+	IdleClose time.Duration
+	// LockTime bounds how long a handle may stay open continuously before
+	// it is forcibly closed and reopened, releasing the on-disk journal
+	// lock for other processes. Zero disables the bound.
+	LockTime time.Duration
+
+	// LockMode controls how New behaves when another process already holds
+	// the OS-level advisory lock on this Path (see procklock.go). The zero
+	// value is LockFailFast.
+	LockMode LockMode
+	// LockWaitCallback, if set, is invoked each time the process lock is
+	// acquired for this handle (by New, and again on LockTime recycles),
+	// with how long the acquire took (close to zero if uncontended). Useful
+	// for progress reporting under LockBlock / LockBlockWithTimeout.
+	LockWaitCallback func(waited time.Duration)
+
+	// Mode selects the transport New uses to talk to Dolt. The zero value
+	// is ModeEmbedded.
+	Mode Mode
+	// Server configures Mode == ModeServer. Unused in embedded mode.
+	Server ServerConfig
+	// Embedded tunes the embedded driver's DSN params (retry envelope,
+	// caching, lock behavior). Unused in server mode.
+	Embedded EmbeddedConfig
+}
+
+// EmbeddedConfig tunes the embedded dolthub/driver DSN params built by
+// embeddedDefaultOpenParams. Zero-valued fields fall back to beads'
+// defaults (see embedded_dsn.go).
+type EmbeddedConfig struct {
+	RetryTimeout      time.Duration
+	RetryMaxAttempts  int
+	RetryInitialDelay time.Duration
+	RetryMaxDelay     time.Duration
+
+	// DisableCache controls the embedded driver's "nocache" param. Nil
+	// defaults to true (disabled), matching beads' historical behavior of
+	// avoiding cross-command stale state.
+	DisableCache *bool
+	// FailOnLockTimeout controls the embedded driver's "failonlocktimeout"
+	// param. Nil defaults to true.
+	FailOnLockTimeout *bool
+
+	// ExtraDSNParams is merged into the embedded DSN last, after all of the
+	// above, and can override any of them.
+	ExtraDSNParams url.Values
+}
+
+// Mode selects how a DoltStore talks to Dolt.
+type Mode string
+
+const (
+	// ModeEmbedded opens an in-process embedded Dolt database (the
+	// dolthub/driver "dolt" database/sql driver). This is the default.
+	ModeEmbedded Mode = "embedded"
+	// ModeServer connects over the MySQL protocol to a dolt sqlserver,
+	// either launched and supervised by New (Config.Server.Managed) or
+	// already running.
+	ModeServer Mode = "server"
+)
+
+// DoltStore is a handle to an open Dolt database, embedded or server-backed.
+//
+// Multiple embedded DoltStore values may share the same underlying
+// connection (see registry.go): New keyed on the same Path+Database returns
+// a handle to the same entry, reference-counted so only the last Close
+// actually tears it down. Server-mode handles are not shared this way.
+type DoltStore struct {
+	entry *handleEntry // embedded mode only
+	conn  *sql.DB      // server mode only
+	proc  *serverProcess
+	cfg   Config
+}
+
+func (s *DoltStore) db() *sql.DB {
+	if s.entry != nil {
+		s.entry.mu.Lock()
+		defer s.entry.mu.Unlock()
+		return s.entry.db
+	}
+	return s.conn
+}
+
+// New opens a DoltStore for cfg, picking embedded or server transport based
+// on cfg.Mode. Callers see the same DoltStore API regardless of transport.
+func New(ctx context.Context, cfg *Config) (*DoltStore, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("dolt: nil Config")
+	}
+	switch cfg.Mode {
+	case "", ModeEmbedded:
+		return newHandle(ctx, cfg)
+	case ModeServer:
+		return newServerHandle(ctx, cfg)
+	default:
+		return nil, fmt.Errorf("dolt: unknown Config.Mode %q", cfg.Mode)
+	}
+}
+
+func openEmbedded(ctx context.Context, cfg *Config) (*sql.DB, error) {
+	initDSN := embeddedInitDSN(cfg, embeddedDefaultOpenParams(cfg))
+	init, err := sql.Open("dolt", initDSN)
+	if err != nil {
+		return nil, fmt.Errorf("dolt: open init DSN: %w", err)
+	}
+	if err := init.PingContext(ctx); err != nil {
+		_ = init.Close()
+		return nil, fmt.Errorf("dolt: ping init DSN: %w", err)
+	}
+	if cfg.Database != "" {
+		quoted := "`" + strings.ReplaceAll(cfg.Database, "`", "``") + "`"
+		if _, err := init.ExecContext(ctx, "CREATE DATABASE IF NOT EXISTS "+quoted); err != nil {
+			_ = init.Close()
+			return nil, fmt.Errorf("dolt: create database %q: %w", cfg.Database, err)
+		}
+	}
+	_ = init.Close()
+
+	dbDSN := embeddedDBDSN(cfg, embeddedDefaultOpenParams(cfg))
+	db, err := sql.Open("dolt", dbDSN)
+	if err != nil {
+		return nil, fmt.Errorf("dolt: open database DSN: %w", err)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("dolt: ping database DSN: %w", err)
+	}
+	const createTable = `CREATE TABLE IF NOT EXISTS beads_config (
+		config_key VARCHAR(255) PRIMARY KEY,
+		config_value LONGTEXT NOT NULL
+	)`
+	if _, err := db.ExecContext(ctx, createTable); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("dolt: create beads_config table: %w", err)
+	}
+	return db, nil
+}
+
+// Close releases this handle. In embedded mode the underlying connection is
+// only torn down once every outstanding handle for the same Path+Database
+// has been closed; in server mode the connection (and any managed
+// dolt sqlserver process) is closed immediately.
+func (s *DoltStore) Close() error {
+	if s.entry != nil {
+		return releaseHandle(s)
+	}
+	return closeServerHandle(s)
+}
+
+// SetConfig persists a single key/value pair in the store's config table.
+func (s *DoltStore) SetConfig(ctx context.Context, key, value string) error {
+	db := s.db()
+	if db == nil {
+		return fmt.Errorf("dolt: store is not currently serving (lock reacquire failed; retrying in the background)")
+	}
+	_, err := db.ExecContext(ctx, "REPLACE INTO beads_config (config_key, config_value) VALUES (?, ?)", key, value)
+	if err != nil {
+		return fmt.Errorf("dolt: set config %q: %w", key, err)
+	}
+	return nil
+}
+
+// GetConfig reads a single key from the store's config table.
+func (s *DoltStore) GetConfig(ctx context.Context, key string) (string, error) {
+	db := s.db()
+	if db == nil {
+		return "", fmt.Errorf("dolt: store is not currently serving (lock reacquire failed; retrying in the background)")
+	}
+	var value string
+	err := db.QueryRowContext(ctx, "SELECT config_value FROM beads_config WHERE config_key = ?", key).Scan(&value)
+	if err != nil {
+		return "", fmt.Errorf("dolt: get config %q: %w", key, err)
+	}
+	return value, nil
+}
@@ -0,0 +1,81 @@
+package dolt
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func boolPtr(b bool) *bool { return &b }
+
+func TestEmbeddedDefaultOpenParams_OverridesApplied(t *testing.T) {
+	cfg := &Config{
+		Path:     "/tmp/beads dolt dbs",
+		Database: "beads",
+		Embedded: EmbeddedConfig{
+			RetryTimeout:      30 * time.Second,
+			RetryMaxAttempts:  5,
+			RetryInitialDelay: 1 * time.Millisecond,
+			RetryMaxDelay:     2 * time.Second,
+			DisableCache:      boolPtr(false),
+			FailOnLockTimeout: boolPtr(false),
+			ExtraDSNParams:    url.Values{"retrytimeout": []string{"99s"}},
+		},
+	}
+
+	q := embeddedDefaultOpenParams(cfg)
+
+	if got := q.Get("retrytimeout"); got != "99s" {
+		t.Fatalf("expected ExtraDSNParams to win over Embedded.RetryTimeout, got %q", got)
+	}
+	if got := q.Get("retrymaxattempts"); got != "5" {
+		t.Fatalf("retrymaxattempts mismatch: got %q want 5", got)
+	}
+	if got := q.Get("retryinitialdelay"); got != "1ms" {
+		t.Fatalf("retryinitialdelay mismatch: got %q want 1ms", got)
+	}
+	if got := q.Get("retrymaxdelay"); got != "2s" {
+		t.Fatalf("retrymaxdelay mismatch: got %q want 2s", got)
+	}
+	if got := q.Get("nocache"); got != "false" {
+		t.Fatalf("nocache mismatch: got %q want false", got)
+	}
+	if got := q.Get("failonlocktimeout"); got != "false" {
+		t.Fatalf("failonlocktimeout mismatch: got %q want false", got)
+	}
+}
+
+// TestEmbeddedDefaultOpenParams_Matrix exercises each tuning knob in
+// isolation against its documented default, the way the go-sql-driver mysql
+// CI matrix exercises each driver option against its default.
+func TestEmbeddedDefaultOpenParams_Matrix(t *testing.T) {
+	cases := []struct {
+		name     string
+		embedded EmbeddedConfig
+		param    string
+		want     string
+	}{
+		{"retryTimeout default", EmbeddedConfig{}, "retrytimeout", "2s"},
+		{"retryTimeout override", EmbeddedConfig{RetryTimeout: 5 * time.Second}, "retrytimeout", "5s"},
+		{"retryMaxAttempts default", EmbeddedConfig{}, "retrymaxattempts", "200"},
+		{"retryMaxAttempts override", EmbeddedConfig{RetryMaxAttempts: 3}, "retrymaxattempts", "3"},
+		{"retryInitialDelay default", EmbeddedConfig{}, "retryinitialdelay", "10ms"},
+		{"retryInitialDelay override", EmbeddedConfig{RetryInitialDelay: 50 * time.Millisecond}, "retryinitialdelay", "50ms"},
+		{"retryMaxDelay default", EmbeddedConfig{}, "retrymaxdelay", "100ms"},
+		{"retryMaxDelay override", EmbeddedConfig{RetryMaxDelay: 250 * time.Millisecond}, "retrymaxdelay", "250ms"},
+		{"disableCache default", EmbeddedConfig{}, "nocache", "true"},
+		{"disableCache override", EmbeddedConfig{DisableCache: boolPtr(false)}, "nocache", "false"},
+		{"failOnLockTimeout default", EmbeddedConfig{}, "failonlocktimeout", "true"},
+		{"failOnLockTimeout override", EmbeddedConfig{FailOnLockTimeout: boolPtr(false)}, "failonlocktimeout", "false"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{Path: "/tmp/x", Database: "beads", Embedded: tc.embedded}
+			q := embeddedDefaultOpenParams(cfg)
+			if got := q.Get(tc.param); got != tc.want {
+				t.Fatalf("%s: got %q want %q", tc.param, got, tc.want)
+			}
+		})
+	}
+}
@@ -2,7 +2,9 @@ package dolt
 
 import (
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Embedded Dolt DSN construction
@@ -12,35 +14,77 @@ import (
 // we intentionally do NOT URL-escape cfg.Path. We only URL-encode the query params.
 //
 // NOTE: This file intentionally only targets embedded mode ("dolt" database/sql driver).
-// Server mode connections use the MySQL protocol driver and do not accept these DSN params.
+// Server mode connections use the MySQL protocol driver and do not accept these DSN
+// params; see server.go for that DSN builder.
 
-func embeddedDefaultTuningParams() url.Values {
+// Default values applied where the corresponding EmbeddedConfig field is unset.
+const (
+	defaultRetryTimeout      = 2 * time.Second
+	defaultRetryMaxAttempts  = 200
+	defaultRetryInitialDelay = 10 * time.Millisecond
+	defaultRetryMaxDelay     = 100 * time.Millisecond
+)
+
+func embeddedTuningParams(e EmbeddedConfig) url.Values {
 	// These are embedded driver DSN params (see dolthub/driver):
 	// - nocache=true disables Dolt's in-process singleton DB cache (avoid cross-command stale state).
 	// - failonlocktimeout=true fails fast on journal lock timeout (avoid "stuck read-only" fallbacks).
+	disableCache := true
+	if e.DisableCache != nil {
+		disableCache = *e.DisableCache
+	}
+	failOnLockTimeout := true
+	if e.FailOnLockTimeout != nil {
+		failOnLockTimeout = *e.FailOnLockTimeout
+	}
 	return url.Values{
-		"nocache":           []string{"true"},
-		"failonlocktimeout": []string{"true"},
+		"nocache":           []string{strconv.FormatBool(disableCache)},
+		"failonlocktimeout": []string{strconv.FormatBool(failOnLockTimeout)},
 	}
 }
 
-func embeddedDefaultRetryParams() url.Values {
+func embeddedRetryParams(e EmbeddedConfig) url.Values {
 	// These are embedded driver DSN params (see dolthub/driver):
 	// - retry=true enables retry for transient embedded contention (lock/readonly/manifest cases).
 	// - retrytimeout bounds total retry time (fail fast-ish, but smooths short-lived contention).
 	// - retrymaxattempts is set high so retrytimeout is the primary bound.
+	timeout := e.RetryTimeout
+	if timeout <= 0 {
+		timeout = defaultRetryTimeout
+	}
+	maxAttempts := e.RetryMaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultRetryMaxAttempts
+	}
+	initialDelay := e.RetryInitialDelay
+	if initialDelay <= 0 {
+		initialDelay = defaultRetryInitialDelay
+	}
+	maxDelay := e.RetryMaxDelay
+	if maxDelay <= 0 {
+		maxDelay = defaultRetryMaxDelay
+	}
 	return url.Values{
 		"retry":             []string{"true"},
-		"retrytimeout":      []string{"2s"},
-		"retrymaxattempts":  []string{"200"},
-		"retryinitialdelay": []string{"10ms"},
-		"retrymaxdelay":     []string{"100ms"},
+		"retrytimeout":      []string{timeout.String()},
+		"retrymaxattempts":  []string{strconv.Itoa(maxAttempts)},
+		"retryinitialdelay": []string{initialDelay.String()},
+		"retrymaxdelay":     []string{maxDelay.String()},
 	}
 }
 
-func embeddedDefaultOpenParams() url.Values {
-	v := embeddedDefaultTuningParams()
-	mergeURLValues(v, embeddedDefaultRetryParams())
+// embeddedDefaultOpenParams builds the embedded DSN tuning/retry params for
+// cfg, applying EmbeddedConfig overrides over the package defaults.
+// cfg.Embedded.ExtraDSNParams is merged in last, so it can override anything
+// above (mergeURLValues has overwrite semantics).
+func embeddedDefaultOpenParams(cfg *Config) url.Values {
+	var e EmbeddedConfig
+	if cfg != nil {
+		e = cfg.Embedded
+	}
+	v := embeddedTuningParams(e)
+	mergeURLValues(v, embeddedRetryParams(e))
+	mergeURLValues(v, e.ExtraDSNParams)
 	return v
 }
 
@@ -4,6 +4,7 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 )
 
 func parseEmbeddedDSN(t *testing.T, dsn string) (dir string, params url.Values) {
@@ -36,8 +37,8 @@ func TestEmbeddedDSN_DefaultOpenParams_Applied(t *testing.T) {
 		Database:       "beads",
 	}
 
-	initDSN := embeddedInitDSN(cfg, embeddedDefaultOpenParams())
-	mainDSN := embeddedDBDSN(cfg, embeddedDefaultOpenParams())
+	initDSN := embeddedInitDSN(cfg, embeddedDefaultOpenParams(cfg))
+	mainDSN := embeddedDBDSN(cfg, embeddedDefaultOpenParams(cfg))
 
 	initDir, initQ := parseEmbeddedDSN(t, initDSN)
 	mainDir, mainQ := parseEmbeddedDSN(t, mainDSN)
@@ -100,5 +101,48 @@ func TestEmbeddedDSN_DefaultOpenParams_Applied(t *testing.T) {
 			t.Fatalf("expected retrymaxdelay=100ms, got %q", got)
 		}
 	}
+
+	// Overridden tuning/retry params (embedded only): same init/main DSN
+	// pair, but with Config.Embedded set, checking the overrides (not the
+	// defaults) land in both DSNs.
+	overrideCfg := &Config{
+		Path:           cfg.Path,
+		CommitterName:  cfg.CommitterName,
+		CommitterEmail: cfg.CommitterEmail,
+		Database:       cfg.Database,
+		Embedded: EmbeddedConfig{
+			RetryTimeout:      30 * time.Second,
+			RetryMaxAttempts:  5,
+			RetryInitialDelay: 1 * time.Millisecond,
+			RetryMaxDelay:     2 * time.Second,
+			DisableCache:      boolPtr(false),
+			FailOnLockTimeout: boolPtr(false),
+		},
+	}
+	overrideInitDSN := embeddedInitDSN(overrideCfg, embeddedDefaultOpenParams(overrideCfg))
+	overrideMainDSN := embeddedDBDSN(overrideCfg, embeddedDefaultOpenParams(overrideCfg))
+	_, overrideInitQ := parseEmbeddedDSN(t, overrideInitDSN)
+	_, overrideMainQ := parseEmbeddedDSN(t, overrideMainDSN)
+
+	for _, q := range []url.Values{overrideInitQ, overrideMainQ} {
+		if got := q.Get("nocache"); got != "false" {
+			t.Fatalf("expected overridden nocache=false, got %q", got)
+		}
+		if got := q.Get("failonlocktimeout"); got != "false" {
+			t.Fatalf("expected overridden failonlocktimeout=false, got %q", got)
+		}
+		if got := q.Get("retrytimeout"); got != "30s" {
+			t.Fatalf("expected overridden retrytimeout=30s, got %q", got)
+		}
+		if got := q.Get("retrymaxattempts"); got != "5" {
+			t.Fatalf("expected overridden retrymaxattempts=5, got %q", got)
+		}
+		if got := q.Get("retryinitialdelay"); got != "1ms" {
+			t.Fatalf("expected overridden retryinitialdelay=1ms, got %q", got)
+		}
+		if got := q.Get("retrymaxdelay"); got != "2s" {
+			t.Fatalf("expected overridden retrymaxdelay=2s, got %q", got)
+		}
+	}
 }
 
@@ -0,0 +1,16 @@
+package dolt
+
+import (
+	"os/exec"
+	"testing"
+)
+
+// skipIfNoDolt skips the test when the dolt binary isn't on PATH, since
+// several tests in this package shell out to it (e.g. managed server mode)
+// or otherwise depend on a real embedded Dolt environment.
+func skipIfNoDolt(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("dolt"); err != nil {
+		t.Skip("dolt binary not found on PATH")
+	}
+}
@@ -12,9 +12,9 @@ import (
 )
 
 // This test uses a helper subprocess to create real cross-process embedded Dolt contention.
-// It verifies:
-// - With fail-fast lock mode and retries disabled, opening the DB while another process holds it fails.
-// - With beads' embedded defaults (fail-fast + retries), opening succeeds once the lock is released.
+// It verifies that with LockBlockWithTimeout, a process waiting on another process's handle
+// is woken deterministically by the OS-level advisory lock (procklock.go) as soon as that
+// handle releases it, rather than depending on Dolt's own manifest-lock retry/backoff envelope.
 func TestEmbeddedLockContention_RetrySucceeds(t *testing.T) {
 	skipIfNoDolt(t)
 
@@ -39,7 +39,6 @@ func TestEmbeddedLockContention_RetrySucceeds(t *testing.T) {
 			fmt.Fprintf(os.Stderr, "helper failed to open store: %v\n", err)
 			os.Exit(2)
 		}
-		defer store.Close()
 
 		// Touch a write to ensure we're in writer-capable mode.
 		_ = store.SetConfig(ctx, "lock_helper_alive", "1")
@@ -52,11 +51,22 @@ func TestEmbeddedLockContention_RetrySucceeds(t *testing.T) {
 		deadline := time.Now().Add(30 * time.Second)
 		for time.Now().Before(deadline) {
 			if _, err := os.Stat(releasePath); err == nil {
+				// Close() alone does not release the process lock — the
+				// handle's IdleClose window keeps the connection (and the
+				// lock that goes with it) alive past the last Close, see
+				// registry.go. What actually releases the flock here is this
+				// process exiting right after we return, which the kernel
+				// treats as closing every fd (and flock) this process still
+				// holds. Observe the release file and return as close to
+				// that exit as possible, so the waiter's wake-up latency
+				// reflects only the OS lock handoff, not our poll interval.
+				_ = store.Close()
 				return
 			}
-			time.Sleep(20 * time.Millisecond)
+			time.Sleep(2 * time.Millisecond)
 		}
 		fmt.Fprintln(os.Stderr, "helper timed out waiting for release file")
+		_ = store.Close()
 		os.Exit(2)
 	}
 
@@ -116,26 +126,36 @@ func TestEmbeddedLockContention_RetrySucceeds(t *testing.T) {
 		}
 	}
 
+	var lockWait time.Duration
 	cfg := &Config{
 		Path:           tmpDir,
 		CommitterName:  "lock-test",
 		CommitterEmail: "lock-test@test.invalid",
 		Database:       "testdb",
+		// Block on the OS-level advisory lock instead of racing the
+		// embedded driver's own manifest-lock retry envelope.
+		LockMode:         LockBlockWithTimeout(10 * time.Second),
+		LockWaitCallback: func(waited time.Duration) { lockWait = waited },
 	}
 
-	// Now validate the beads path: open should succeed once the helper releases (within retry window).
+	// Now validate the beads path: open should succeed once the helper releases.
 	type openResult struct {
 		store   *DoltStore
 		elapsed time.Duration
 		err     error
 	}
 	ch := make(chan openResult, 1)
+	attemptStartCh := make(chan time.Time, 1)
 
 	start := time.Now()
 	go func() {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
+		// Recorded right before the actual acquire attempt, so the
+		// lock-wait assertion below isn't polluted by goroutine scheduling
+		// or setup latency that happened before New was even called.
+		attemptStartCh <- time.Now()
 		store, err := New(ctx, cfg)
 		if err != nil {
 			ch <- openResult{err: err, elapsed: time.Since(start)}
@@ -152,15 +172,18 @@ func TestEmbeddedLockContention_RetrySucceeds(t *testing.T) {
 		ch <- openResult{store: store, elapsed: time.Since(start)}
 	}()
 
-	// Hold the lock a bit to force at least one retry attempt, then release.
-	time.Sleep(500 * time.Millisecond)
+	// Hold the lock a bit to force the waiter to actually block, then release.
+	time.Sleep(200 * time.Millisecond)
+	releaseStart := time.Now()
 	if err := os.WriteFile(releasePath, []byte("release\n"), 0o600); err != nil {
 		t.Fatalf("failed to write release file: %v", err)
 	}
 
 	var res openResult
+	var attemptStart time.Time
 	select {
 	case res = <-ch:
+		attemptStart = <-attemptStartCh
 	case <-time.After(12 * time.Second):
 		t.Fatalf("timed out waiting for open attempt to finish; helper output:\n%s", helperOut.String())
 	}
@@ -180,12 +203,24 @@ func TestEmbeddedLockContention_RetrySucceeds(t *testing.T) {
 	}
 
 	// The open should not complete before we release the lock (otherwise contention wasn't real).
-	if res.elapsed < 450*time.Millisecond {
+	if res.elapsed < 150*time.Millisecond {
 		t.Fatalf("open completed too quickly (%v); expected to wait for lock release", res.elapsed)
 	}
+
+	// The lock-wait component specifically — not the whole open, which also
+	// includes real embedded-Dolt startup work — should resolve deterministically
+	// soon after the release file write, since flock/LockFileEx handoff is an OS
+	// notification rather than a polled backoff. This is the behavior procklock.go
+	// replaces the old retry-envelope reliance with. lockGrantedAt is measured from
+	// attemptStart (recorded just before New was called), not the outer start, so it
+	// isn't inflated by goroutine-dispatch or MkdirAll/OpenFile setup latency.
+	lockGrantedAt := attemptStart.Add(lockWait)
+	if gap := lockGrantedAt.Sub(releaseStart); gap < -5*time.Millisecond || gap > 150*time.Millisecond {
+		t.Fatalf("lock wait resolved %v after release (want within ~150ms); helper output:\n%s", gap, helperOut.String())
+	}
+
 	// The open should not take unbounded time; it should complete soon after release.
 	if res.elapsed > 8*time.Second {
 		t.Fatalf("open took too long: %v", res.elapsed)
 	}
 }
-
@@ -0,0 +1,110 @@
+package dolt
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestLockTime_RecycleSelfHeals exercises Config.LockTime end to end: the
+// lock timer armed by startLockTimerLocked should periodically recycle the
+// embedded connection (dropping and reacquiring the process lock so another
+// beads process gets a chance at it), and if that reacquire loses the race
+// it should leave the entry not currently serving rather than keep handing
+// out a connection with no advisory lock backing it — then self-heal via
+// reopenEntry once the lock is free again. This is the only coverage for
+// recycleEntry/reopenEntry/scheduleReopen; without it the SetConfig/GetConfig
+// "not currently serving" branches in dolt.go are entirely unexercised.
+func TestLockTime_RecycleSelfHeals(t *testing.T) {
+	skipIfNoDolt(t)
+
+	tmpDir := t.TempDir()
+	cfg := &Config{
+		Path:           tmpDir,
+		CommitterName:  "lock-time-test",
+		CommitterEmail: "lock-time-test@test.invalid",
+		Database:       "testdb",
+		LockTime:       150 * time.Millisecond,
+	}
+	ctx := context.Background()
+
+	store, err := New(ctx, cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	key, err := handleKey(cfg)
+	if err != nil {
+		t.Fatalf("handleKey: %v", err)
+	}
+	dbMapMu.Lock()
+	entry := dbMap[key]
+	dbMapMu.Unlock()
+	if entry == nil {
+		t.Fatalf("expected a registered handle entry")
+	}
+
+	// Drop the entry's own advisory lock first, the way the recycle gap
+	// would, so another holder can take it before the lock timer fires.
+	entry.mu.Lock()
+	originalDB := entry.db
+	_ = entry.plock.release()
+	entry.mu.Unlock()
+
+	// Hold the advisory lock externally (a distinct open file description,
+	// so it contends with entry.plock even from within this same process)
+	// so the lock timer's first reacquire attempt loses the race.
+	external, err := acquireProcessLock(ctx, tmpDir, LockFailFast, nil)
+	if err != nil {
+		t.Fatalf("acquire external lock: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		entry.mu.Lock()
+		stoppedServing := entry.db == nil
+		entry.mu.Unlock()
+		if stoppedServing {
+			break
+		}
+		if time.Now().After(deadline) {
+			_ = external.release()
+			t.Fatalf("expected a failed reacquire to leave entry.db nil")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, err := store.GetConfig(ctx, "missing_key"); err == nil {
+		t.Fatalf("expected GetConfig to report the store is not currently serving")
+	}
+
+	if err := external.release(); err != nil {
+		t.Fatalf("release external lock: %v", err)
+	}
+
+	deadline = time.Now().Add(5 * time.Second)
+	for {
+		entry.mu.Lock()
+		healed := entry.db != nil && entry.db != originalDB
+		entry.mu.Unlock()
+		if healed {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected reopenEntry to self-heal once the lock was free")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := store.SetConfig(ctx, "lock_time_test", "1"); err != nil {
+		t.Fatalf("SetConfig after self-heal: %v", err)
+	}
+	got, err := store.GetConfig(ctx, "lock_time_test")
+	if err != nil {
+		t.Fatalf("GetConfig after self-heal: %v", err)
+	}
+	if got != "1" {
+		t.Fatalf("GetConfig after self-heal mismatch: got %q want %q", got, "1")
+	}
+}
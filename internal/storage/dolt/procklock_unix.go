@@ -0,0 +1,47 @@
+//go:build !windows
+
+package dolt
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+)
+
+// lockPollInterval is how often blockLockFile retries a non-blocking
+// flock attempt while waiting for another process to release it.
+const lockPollInterval = 5 * time.Millisecond
+
+func tryLockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+}
+
+func unlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
+
+// blockLockFile polls a non-blocking flock rather than issuing a blocking
+// LOCK_EX directly, so ctx cancellation and an optional timeout (0 means
+// wait indefinitely) are both honored.
+func blockLockFile(ctx context.Context, f *os.File, timeout time.Duration) error {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	for {
+		err := tryLockFile(f)
+		if err == nil {
+			return nil
+		}
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %s waiting for lock: %w", timeout, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
@@ -0,0 +1,50 @@
+//go:build windows
+
+package dolt
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockPollInterval is how often blockLockFile retries a non-blocking
+// LockFileEx attempt while waiting for another process to release it.
+const lockPollInterval = 5 * time.Millisecond
+
+func tryLockFile(f *os.File) error {
+	ol := new(windows.Overlapped)
+	return windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0, ol)
+}
+
+func unlockFile(f *os.File) error {
+	ol := new(windows.Overlapped)
+	return windows.UnlockFileEx(windows.Handle(f.Fd()), 0, 1, 0, ol)
+}
+
+// blockLockFile polls a non-blocking LockFileEx rather than a blocking call,
+// so ctx cancellation and an optional timeout (0 means wait indefinitely)
+// are both honored.
+func blockLockFile(ctx context.Context, f *os.File, timeout time.Duration) error {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	for {
+		err := tryLockFile(f)
+		if err == nil {
+			return nil
+		}
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %s waiting for lock: %w", timeout, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
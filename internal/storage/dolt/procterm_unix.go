@@ -0,0 +1,14 @@
+//go:build !windows
+
+package dolt
+
+import (
+	"os"
+	"syscall"
+)
+
+// terminateProcess asks p to shut down gracefully via SIGTERM, the signal
+// `dolt sqlserver` handles for a clean shutdown.
+func terminateProcess(p *os.Process) error {
+	return p.Signal(syscall.SIGTERM)
+}
@@ -0,0 +1,12 @@
+//go:build windows
+
+package dolt
+
+import "os"
+
+// terminateProcess shuts p down. os.Process.Signal only supports os.Kill on
+// Windows (anything else returns syscall.EWINDOWS), so there's no portable
+// graceful-signal equivalent to SIGTERM here.
+func terminateProcess(p *os.Process) error {
+	return p.Kill()
+}
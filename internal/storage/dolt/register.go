@@ -0,0 +1,42 @@
+package dolt
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/coffeegoddd/beads/internal/storage"
+)
+
+var _ storage.Store = (*DoltStore)(nil)
+
+func init() {
+	// "dolt" dispatches on cfg.Mode (embedded by default); "dolt-server" is
+	// a convenience alias for callers that always want server mode and
+	// would otherwise have to remember to set Mode themselves.
+	storage.Register("dolt", openStore)
+	storage.Register("dolt-server", openServerStore)
+}
+
+// openStore adapts New to the storage.Opener shape so the dolt backend can
+// be selected by name via storage.Open instead of importing this package
+// directly.
+func openStore(ctx context.Context, options interface{}) (storage.Store, error) {
+	cfg, ok := options.(*Config)
+	if !ok {
+		return nil, fmt.Errorf("dolt: storage.Open options must be *dolt.Config, got %T", options)
+	}
+	return New(ctx, cfg)
+}
+
+func openServerStore(ctx context.Context, options interface{}) (storage.Store, error) {
+	cfg, ok := options.(*Config)
+	if !ok {
+		return nil, fmt.Errorf("dolt: storage.Open options must be *dolt.Config, got %T", options)
+	}
+	// Copy before mutating Mode: cfg belongs to the caller, who may reuse it
+	// (e.g. for a later embedded storage.Open) and shouldn't see it silently
+	// switched to server mode.
+	cfgCopy := *cfg
+	cfgCopy.Mode = ModeServer
+	return New(ctx, &cfgCopy)
+}
@@ -0,0 +1,376 @@
+package dolt
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// defaultIdleClose mirrors the idle-close behaviour of rclone's lib/kv
+// bolt handle cache: a shared handle outlives its last reference briefly so
+// that back-to-back opens in the same process (e.g. CLI subcommands run in
+// a loop) don't pay the cost of tearing down and reopening the embedded DB.
+const defaultIdleClose = 30 * time.Second
+
+// recycleRetryInterval bounds how soon a failed recycle attempt (lock
+// reacquire or embedded open error) is retried.
+const recycleRetryInterval = 1 * time.Second
+
+// handleEntry is a single shared, reference-counted embedded connection.
+//
+// entry.plock is only ever released at the same time entry.db is closed
+// (in closeIdleEntry and recycleEntry) — never while entry.db is still
+// open — so a live entry.db always implies the process lock is held. That
+// invariant is what lets another process trust "resource temporarily
+// unavailable" on the advisory lock to mean this process still holds
+// Dolt's journal/manifest lock too, instead of silently falling back onto
+// Dolt's own manifest-lock retry envelope.
+type handleEntry struct {
+	mu    sync.Mutex
+	db    *sql.DB
+	plock *processLock
+	cfg   Config
+	key   string
+
+	refs      int
+	idleTimer *time.Timer
+	lockTimer *time.Timer
+
+	// lockMu serializes closeIdleEntry and recycleEntry, both of which
+	// release/reacquire entry.plock outside of entry.mu (acquireProcessLock
+	// can block).
+	lockMu sync.Mutex
+}
+
+var (
+	dbMapMu sync.Mutex
+	dbMap   = map[string]*handleEntry{}
+)
+
+func handleKey(cfg *Config) (string, error) {
+	abs, err := filepath.Abs(cfg.Path)
+	if err != nil {
+		return "", fmt.Errorf("dolt: resolve path %q: %w", cfg.Path, err)
+	}
+	return abs + "\x00" + cfg.Database, nil
+}
+
+// newHandle returns a DoltStore backed by a shared, reference-counted
+// connection for cfg.Path+cfg.Database, opening one if none is live.
+func newHandle(ctx context.Context, cfg *Config) (*DoltStore, error) {
+	key, err := handleKey(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	dbMapMu.Lock()
+	entry, ok := dbMap[key]
+	dbMapMu.Unlock()
+	if ok {
+		if store, reused := reuseHandle(entry, cfg); reused {
+			return store, nil
+		}
+		// entry was torn down (closeIdleEntry/recycleEntry) between our
+		// lookup and reuseHandle's check of entry.db; fall through and open
+		// (and register) a fresh one below, replacing the dead entry.
+	}
+
+	// Reserve the key while we open the connection, so concurrent New calls
+	// for the same key block on entry.mu rather than racing to open twice.
+	// Lock dbMapMu before entry.mu (matching closeIdleEntry/recycleEntry's
+	// acquire order) even though entry isn't reachable through dbMap until
+	// the insert below: a future change that reorders this is otherwise one
+	// missed review away from a lock-ordering deadlock.
+	entry = &handleEntry{cfg: *cfg, key: key, refs: 1}
+	dbMapMu.Lock()
+	entry.mu.Lock()
+	dbMap[key] = entry
+	dbMapMu.Unlock()
+	// entry.mu is released before each subsequent dbMapMu acquisition below,
+	// rather than held across it via defer: that keeps every dbMapMu+entry.mu
+	// pairing in this package ordered dbMapMu-before-entry.mu, with the two
+	// never nested the other way around.
+
+	plock, err := acquireProcessLock(ctx, cfg.Path, cfg.LockMode, cfg.LockWaitCallback)
+	if err != nil {
+		entry.mu.Unlock()
+		dbMapMu.Lock()
+		if dbMap[key] == entry {
+			delete(dbMap, key)
+		}
+		dbMapMu.Unlock()
+		return nil, err
+	}
+
+	db, err := openEmbedded(ctx, cfg)
+	if err != nil {
+		_ = plock.release()
+		entry.mu.Unlock()
+		dbMapMu.Lock()
+		if dbMap[key] == entry {
+			delete(dbMap, key)
+		}
+		dbMapMu.Unlock()
+		return nil, err
+	}
+	entry.db = db
+	entry.plock = plock
+	entry.startLockTimerLocked()
+	entry.mu.Unlock()
+
+	return &DoltStore{entry: entry, cfg: *cfg}, nil
+}
+
+// reuseHandle bumps the reference count on entry if it's still live, i.e.
+// hasn't been torn down by closeIdleEntry or recycleEntry since the caller
+// looked it up in dbMap (dbMapMu only guards the map itself, not entry's own
+// state, so there's a gap between that lookup and entry.mu.Lock() here).
+// It reports false if the caller should fall back to opening a fresh entry
+// instead of handing back an entry whose connection is already gone.
+//
+// Per handleEntry's invariant, a live entry.db implies the process lock is
+// already held, so reuseHandle never needs to touch plock itself.
+func reuseHandle(entry *handleEntry, cfg *Config) (*DoltStore, bool) {
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.db == nil {
+		return nil, false
+	}
+	if entry.idleTimer != nil {
+		entry.idleTimer.Stop()
+		entry.idleTimer = nil
+	}
+	entry.refs++
+	return &DoltStore{entry: entry, cfg: *cfg}, true
+}
+
+// releaseHandle decrements the reference count for s's shared handle. Once
+// the count reaches zero, the connection (and the process lock backing it)
+// is kept alive for Config.IdleClose, so a New call arriving within the
+// idle window reuses it instead of reopening from disk. The process lock is
+// deliberately *not* released here: per handleEntry's invariant, it's only
+// dropped once entry.db is actually closed (see closeIdleEntry), so this
+// process never holds an open embedded connection — and so the Dolt journal
+// lock that goes with it — without the advisory lock that's supposed to be
+// guarding it.
+func releaseHandle(s *DoltStore) error {
+	entry := s.entry
+	if entry == nil {
+		return nil
+	}
+	s.entry = nil
+
+	entry.mu.Lock()
+	entry.refs--
+	if entry.refs > 0 {
+		entry.mu.Unlock()
+		return nil
+	}
+	if entry.refs < 0 {
+		entry.refs = 0
+	}
+
+	idle := s.cfg.IdleClose
+	if idle <= 0 {
+		idle = defaultIdleClose
+	}
+	entry.idleTimer = time.AfterFunc(idle, func() { closeIdleEntry(entry) })
+	entry.mu.Unlock()
+	return nil
+}
+
+// closeIdleEntry tears down entry's connection and releases its process
+// lock together once its idle timer fires, provided nothing grabbed a new
+// reference in the meantime. lockMu serializes this against recycleEntry,
+// which also releases/reacquires entry.plock.
+func closeIdleEntry(entry *handleEntry) {
+	entry.lockMu.Lock()
+	defer entry.lockMu.Unlock()
+
+	dbMapMu.Lock()
+	entry.mu.Lock()
+	if entry.refs > 0 {
+		entry.mu.Unlock()
+		dbMapMu.Unlock()
+		return
+	}
+	if dbMap[entry.key] == entry {
+		delete(dbMap, entry.key)
+	}
+	dbMapMu.Unlock()
+
+	if entry.lockTimer != nil {
+		entry.lockTimer.Stop()
+		entry.lockTimer = nil
+	}
+	db := entry.db
+	plock := entry.plock
+	entry.db = nil
+	entry.plock = nil
+	entry.mu.Unlock()
+
+	if db != nil {
+		_ = db.Close()
+	}
+	if plock != nil {
+		_ = plock.release()
+	}
+}
+
+// startLockTimerLocked arms entry.lockTimer when cfg.LockTime is set, so a
+// long-held handle periodically releases the on-disk journal lock for other
+// processes even while still in active use. Callers must hold entry.mu.
+func (entry *handleEntry) startLockTimerLocked() {
+	if entry.cfg.LockTime <= 0 {
+		return
+	}
+	entry.lockTimer = time.AfterFunc(entry.cfg.LockTime, func() { recycleEntry(entry) })
+}
+
+// recycleEntry closes and reopens entry's connection in place, without
+// touching its reference count, so that in-flight DoltStore handles keep
+// working against the same *handleEntry once the swap completes. It also
+// drops and reacquires the process lock around the reopen, so the brief gap
+// genuinely gives a waiting process in another beads invocation a chance to
+// acquire it, rather than just cycling Dolt's own journal lock under a lock
+// we never release.
+//
+// The lock release/reacquire and the embedded open/ping are done without
+// holding entry.mu, so db() callers aren't blocked for the duration; entry.mu
+// is only taken to snapshot state up front and to install the result
+// afterward. If the lock can't be reacquired, the entry stops serving rather
+// than continue handing out the old connection with no advisory lock held,
+// and hands off to reopenEntry, which actually retries the acquire+open
+// (recycleEntry can't retry itself: it returns immediately once entry.db is
+// nil, which is exactly the state this failure path leaves it in).
+//
+// entry.lockMu is held for the duration, serializing against
+// closeIdleEntry/reopenEntry's own lock acquire/release for this entry.
+func recycleEntry(entry *handleEntry) {
+	entry.lockMu.Lock()
+	defer entry.lockMu.Unlock()
+
+	entry.mu.Lock()
+	old := entry.db
+	oldLock := entry.plock
+	cfg := entry.cfg
+	entry.mu.Unlock()
+	if old == nil {
+		return
+	}
+
+	if oldLock != nil {
+		_ = oldLock.release()
+	}
+
+	newLock, err := acquireProcessLock(context.Background(), cfg.Path, cfg.LockMode, cfg.LockWaitCallback)
+	if err != nil {
+		// We just gave up our own lock and couldn't get it back: we must
+		// not keep serving the old connection unprotected. Stop serving and
+		// let reopenEntry take over retrying the acquire+open from scratch.
+		entry.mu.Lock()
+		if entry.db == old {
+			_ = old.Close()
+			entry.db = nil
+			entry.plock = nil
+		}
+		entry.mu.Unlock()
+		scheduleReopen(entry)
+		return
+	}
+
+	db, err := openEmbedded(context.Background(), &cfg)
+	if err != nil {
+		// Keep serving the old connection under the newly reacquired lock;
+		// retry the reopen on the next tick.
+		entry.mu.Lock()
+		if entry.db == old {
+			entry.plock = newLock
+			entry.startLockTimerLocked()
+		} else {
+			_ = newLock.release()
+		}
+		entry.mu.Unlock()
+		return
+	}
+
+	entry.mu.Lock()
+	if entry.db == old {
+		entry.db = db
+		entry.plock = newLock
+		entry.startLockTimerLocked()
+	} else {
+		// Entry was torn down (idle-closed) while we were reopening.
+		_ = db.Close()
+		_ = newLock.release()
+	}
+	entry.mu.Unlock()
+	_ = old.Close()
+}
+
+// scheduleReopen arms entry.lockTimer to call reopenEntry after
+// recycleRetryInterval. Callers must not be holding entry.mu.
+func scheduleReopen(entry *handleEntry) {
+	entry.mu.Lock()
+	entry.lockTimer = time.AfterFunc(recycleRetryInterval, func() { reopenEntry(entry) })
+	entry.mu.Unlock()
+}
+
+// reopenEntry retries acquiring the process lock and opening a fresh
+// embedded connection for an entry left with entry.db == nil by recycleEntry
+// (lock reacquire failed). Unlike recycleEntry, it doesn't bail out when
+// entry.db is nil — that's the state it exists to repair — so it reschedules
+// itself every recycleRetryInterval until it succeeds, or until entry is
+// evicted from dbMap (nothing references it any more, and any future New
+// call for the same key will just open a brand new entry instead).
+func reopenEntry(entry *handleEntry) {
+	entry.lockMu.Lock()
+	defer entry.lockMu.Unlock()
+
+	entry.mu.Lock()
+	if entry.db != nil {
+		// Someone else already reopened it.
+		entry.mu.Unlock()
+		return
+	}
+	cfg := entry.cfg
+	entry.mu.Unlock()
+
+	dbMapMu.Lock()
+	live := dbMap[entry.key] == entry
+	dbMapMu.Unlock()
+	if !live {
+		return
+	}
+
+	plock, err := acquireProcessLock(context.Background(), cfg.Path, cfg.LockMode, cfg.LockWaitCallback)
+	if err != nil {
+		entry.mu.Lock()
+		entry.lockTimer = time.AfterFunc(recycleRetryInterval, func() { reopenEntry(entry) })
+		entry.mu.Unlock()
+		return
+	}
+
+	db, err := openEmbedded(context.Background(), &cfg)
+	if err != nil {
+		_ = plock.release()
+		entry.mu.Lock()
+		entry.lockTimer = time.AfterFunc(recycleRetryInterval, func() { reopenEntry(entry) })
+		entry.mu.Unlock()
+		return
+	}
+
+	entry.mu.Lock()
+	if entry.db == nil {
+		entry.db = db
+		entry.plock = plock
+		entry.startLockTimerLocked()
+	} else {
+		_ = db.Close()
+		_ = plock.release()
+	}
+	entry.mu.Unlock()
+}
@@ -0,0 +1,104 @@
+package dolt
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNew_SameProcessSharesHandle(t *testing.T) {
+	skipIfNoDolt(t)
+
+	cfg := &Config{
+		Path:           t.TempDir(),
+		CommitterName:  "refcount-test",
+		CommitterEmail: "refcount-test@test.invalid",
+		Database:       "testdb",
+		IdleClose:      50 * time.Millisecond,
+	}
+	ctx := context.Background()
+
+	a, err := New(ctx, cfg)
+	if err != nil {
+		t.Fatalf("first New: %v", err)
+	}
+	b, err := New(ctx, cfg)
+	if err != nil {
+		t.Fatalf("second New: %v", err)
+	}
+	if a.entry != b.entry {
+		t.Fatalf("expected second New in the same process to reuse the first handle's entry")
+	}
+
+	if err := a.Close(); err != nil {
+		t.Fatalf("close a: %v", err)
+	}
+	// b still holds a reference; the entry must still be live.
+	if _, err := b.GetConfig(ctx, "missing_key"); err == nil {
+		t.Fatalf("expected ErrNoRows-style error for missing key, got nil")
+	}
+
+	if err := b.Close(); err != nil {
+		t.Fatalf("close b: %v", err)
+	}
+
+	dbMapMu.Lock()
+	key, err := handleKey(cfg)
+	if err != nil {
+		t.Fatalf("handleKey: %v", err)
+	}
+	_, stillPresent := dbMap[key]
+	dbMapMu.Unlock()
+	if !stillPresent {
+		t.Fatalf("expected entry to remain cached during the idle-close window")
+	}
+
+	time.Sleep(cfg.IdleClose + 100*time.Millisecond)
+
+	dbMapMu.Lock()
+	_, stillPresent = dbMap[key]
+	dbMapMu.Unlock()
+	if stillPresent {
+		t.Fatalf("expected entry to be torn down after the idle-close window elapsed")
+	}
+}
+
+func TestSetGetConfig_RoundTrip(t *testing.T) {
+	skipIfNoDolt(t)
+
+	cfg := &Config{
+		Path:           t.TempDir(),
+		CommitterName:  "config-roundtrip-test",
+		CommitterEmail: "config-roundtrip-test@test.invalid",
+		Database:       "testdb",
+	}
+	ctx := context.Background()
+
+	store, err := New(ctx, cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	if err := store.SetConfig(ctx, "greeting", "hello"); err != nil {
+		t.Fatalf("SetConfig: %v", err)
+	}
+	got, err := store.GetConfig(ctx, "greeting")
+	if err != nil {
+		t.Fatalf("GetConfig: %v", err)
+	}
+	if got != "hello" {
+		t.Fatalf("GetConfig mismatch: got %q want %q", got, "hello")
+	}
+
+	if err := store.SetConfig(ctx, "greeting", "updated"); err != nil {
+		t.Fatalf("SetConfig overwrite: %v", err)
+	}
+	got, err = store.GetConfig(ctx, "greeting")
+	if err != nil {
+		t.Fatalf("GetConfig after overwrite: %v", err)
+	}
+	if got != "updated" {
+		t.Fatalf("GetConfig after overwrite mismatch: got %q want %q", got, "updated")
+	}
+}
@@ -0,0 +1,305 @@
+package dolt
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	"gopkg.in/yaml.v2"
+)
+
+// defaultServerReadyTimeout is the grace period waitForServerReady gives a
+// managed `dolt sqlserver` to come up, applied when ServerConfig.ReadyTimeout
+// is unset. A cold start (opening/creating the data dir, loading the
+// manifest) routinely takes longer than the per-query embedded retry
+// envelope (see embeddedRetryParams in embedded_dsn.go), so this is bounded
+// separately rather than mirroring that constant.
+const defaultServerReadyTimeout = 30 * time.Second
+
+// serverReadyRetryDelay is how long waitForServerReady sleeps between ping
+// attempts while waiting out ServerConfig.ReadyTimeout / defaultServerReadyTimeout.
+const serverReadyRetryDelay = 10 * time.Millisecond
+
+// ServerConfig configures Config.Mode == ModeServer.
+type ServerConfig struct {
+	// Host is the MySQL-protocol host to dial. Defaults to "127.0.0.1".
+	// Ignored if Socket is set.
+	Host string
+	// Port is the MySQL-protocol port to dial. If Managed and Port is 0,
+	// a free port is chosen automatically.
+	Port int
+	// Socket, if set, connects over a Unix socket instead of host:port.
+	Socket string
+
+	User     string
+	Password string
+
+	// Managed, if true, launches and supervises a local `dolt sqlserver`
+	// child process instead of connecting to a server someone else started.
+	Managed bool
+	// DataDir is the Dolt data directory the managed server serves out of.
+	// Required when Managed is true.
+	DataDir string
+	// DoltBin is the dolt binary to exec. Defaults to "dolt" (resolved via
+	// PATH).
+	DoltBin string
+	// Stderr, if set, receives the managed dolt sqlserver process's stderr.
+	Stderr io.Writer
+
+	// ReadyTimeout bounds how long waitForServerReady waits for a managed
+	// server to start accepting connections. Zero uses
+	// defaultServerReadyTimeout. Ignored when Managed is false (an
+	// externally-started server is assumed to already be up).
+	ReadyTimeout time.Duration
+}
+
+// serverProcess tracks a managed `dolt sqlserver` child process.
+type serverProcess struct {
+	cmd        *exec.Cmd
+	socketPath string
+}
+
+// serverConfigYAML is the minimal subset of dolt sqlserver's config.yaml we
+// generate for a managed server.
+//
+// Committer identity is deliberately not part of this struct: dolt sqlserver's
+// config.yaml has no committer-identity field, only a "user_session_vars" list
+// of {name, vars:[...]} entries for per-user session variable presets, which
+// is a different thing entirely. We set the committer via DOLT_COMMITTER_NAME
+// / DOLT_COMMITTER_EMAIL on the child's environment instead (see
+// startManagedServer).
+type serverConfigYAML struct {
+	UserYAML struct {
+		Name     string `yaml:"name,omitempty"`
+		Password string `yaml:"password,omitempty"`
+	} `yaml:"user,omitempty"`
+	DataDir string `yaml:"data_dir"`
+
+	ListenerYAML struct {
+		HostStr string `yaml:"host"`
+		PortNum int    `yaml:"port"`
+		Socket  string `yaml:"socket,omitempty"`
+	} `yaml:"listener"`
+}
+
+func newServerHandle(ctx context.Context, cfg *Config) (*DoltStore, error) {
+	// Work against a copy from here on: startManagedServer resolves an
+	// auto-picked Server.Port in place, and cfg belongs to the caller, who
+	// may reuse it and shouldn't see that leak back into their struct.
+	cfgCopy := *cfg
+	cfg = &cfgCopy
+
+	sc := cfg.Server
+	var proc *serverProcess
+	if sc.Managed {
+		p, err := startManagedServer(cfg)
+		if err != nil {
+			return nil, err
+		}
+		proc = p
+	}
+
+	dsn, err := serverDSN(cfg)
+	if err != nil {
+		stopManagedServer(proc)
+		return nil, err
+	}
+
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		stopManagedServer(proc)
+		return nil, fmt.Errorf("dolt: open server DSN: %w", err)
+	}
+
+	if err := waitForServerReady(ctx, db, sc.ReadyTimeout); err != nil {
+		_ = db.Close()
+		stopManagedServer(proc)
+		return nil, err
+	}
+
+	return &DoltStore{conn: db, proc: proc, cfg: *cfg}, nil
+}
+
+func closeServerHandle(s *DoltStore) error {
+	var err error
+	if s.conn != nil {
+		err = s.conn.Close()
+	}
+	stopManagedServer(s.proc)
+	return err
+}
+
+// serverDSNSpecialChars are the characters that go-sql-driver/mysql's DSN
+// grammar uses as delimiters ("user:password@net(addr)/dbname"). ParseDSN
+// locates them positionally rather than escaping, so a User/Password
+// containing any of these would shift where the driver splits the DSN and
+// either fail to connect or, worse, authenticate as the wrong user.
+const serverDSNSpecialChars = "@:/"
+
+// serverDSN builds the go-sql-driver/mysql DSN for cfg.Server.
+func serverDSN(cfg *Config) (string, error) {
+	sc := cfg.Server
+	if strings.ContainsAny(sc.User, serverDSNSpecialChars) {
+		return "", fmt.Errorf("dolt: Config.Server.User must not contain any of %q", serverDSNSpecialChars)
+	}
+	if strings.ContainsAny(sc.Password, serverDSNSpecialChars) {
+		return "", fmt.Errorf("dolt: Config.Server.Password must not contain any of %q", serverDSNSpecialChars)
+	}
+	addr := ""
+	netw := "tcp"
+	if sc.Socket != "" {
+		netw = "unix"
+		addr = sc.Socket
+	} else {
+		host := sc.Host
+		if host == "" {
+			host = "127.0.0.1"
+		}
+		if sc.Port == 0 {
+			return "", fmt.Errorf("dolt: Config.Server.Port is required (or Socket) in server mode")
+		}
+		addr = fmt.Sprintf("%s:%d", host, sc.Port)
+	}
+	dsn := fmt.Sprintf("%s:%s@%s(%s)/%s", sc.User, sc.Password, netw, addr, cfg.Database)
+	return dsn, nil
+}
+
+func waitForServerReady(ctx context.Context, db *sql.DB, readyTimeout time.Duration) error {
+	if readyTimeout <= 0 {
+		readyTimeout = defaultServerReadyTimeout
+	}
+	deadline := time.Now().Add(readyTimeout)
+	var lastErr error
+	for {
+		pingCtx, cancel := context.WithTimeout(ctx, serverReadyRetryDelay*5)
+		err := db.PingContext(pingCtx)
+		cancel()
+		if err == nil {
+			var one int
+			if err := db.QueryRowContext(ctx, "SELECT 1").Scan(&one); err == nil {
+				return nil
+			} else {
+				lastErr = err
+			}
+		} else {
+			lastErr = err
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("dolt: server not ready after %s: %w", readyTimeout, lastErr)
+		}
+		time.Sleep(serverReadyRetryDelay)
+	}
+}
+
+// buildServerConfigYAML derives the config.yaml content for cfg.Server,
+// assuming sc.Port/sc.Socket are already resolved (see startManagedServer).
+// Pulled out as a pure function so the generated YAML can be asserted on
+// directly in tests, without needing a real dolt binary.
+func buildServerConfigYAML(cfg *Config) serverConfigYAML {
+	sc := &cfg.Server
+	yamlCfg := serverConfigYAML{
+		DataDir: sc.DataDir,
+	}
+	yamlCfg.UserYAML.Name = sc.User
+	yamlCfg.UserYAML.Password = sc.Password
+	yamlCfg.ListenerYAML.HostStr = sc.Host
+	if yamlCfg.ListenerYAML.HostStr == "" {
+		yamlCfg.ListenerYAML.HostStr = "127.0.0.1"
+	}
+	yamlCfg.ListenerYAML.PortNum = sc.Port
+	yamlCfg.ListenerYAML.Socket = sc.Socket
+	return yamlCfg
+}
+
+// startManagedServer picks a free port (if needed), writes a minimal
+// config.yaml, and execs `dolt sqlserver` against it.
+func startManagedServer(cfg *Config) (*serverProcess, error) {
+	sc := &cfg.Server
+	if sc.DataDir == "" {
+		return nil, fmt.Errorf("dolt: Config.Server.DataDir is required for a managed server")
+	}
+	if sc.Socket == "" && sc.Port == 0 {
+		port, err := freePort()
+		if err != nil {
+			return nil, fmt.Errorf("dolt: pick free port: %w", err)
+		}
+		sc.Port = port
+	}
+
+	yamlCfg := buildServerConfigYAML(cfg)
+	out, err := yaml.Marshal(&yamlCfg)
+	if err != nil {
+		return nil, fmt.Errorf("dolt: marshal managed server config: %w", err)
+	}
+	configPath := filepath.Join(sc.DataDir, ".beads-sqlserver-config.yaml")
+	if err := os.WriteFile(configPath, out, 0o600); err != nil {
+		return nil, fmt.Errorf("dolt: write managed server config: %w", err)
+	}
+
+	doltBin := sc.DoltBin
+	if doltBin == "" {
+		doltBin = "dolt"
+	}
+
+	cmd := exec.Command(doltBin, "sqlserver", "-c", configPath) // #nosec G204
+	if sc.Stderr != nil {
+		cmd.Stderr = sc.Stderr
+	}
+	// dolt sqlserver has no config.yaml field for committer identity (the
+	// "user_session_vars" key is for per-user session variable presets, not
+	// this); DOLT_COMMITTER_NAME/DOLT_COMMITTER_EMAIL are the documented way
+	// to set it for a process that doesn't go through `dolt init`/`dolt config`.
+	cmd.Env = append(os.Environ(),
+		"DOLT_COMMITTER_NAME="+cfg.CommitterName,
+		"DOLT_COMMITTER_EMAIL="+cfg.CommitterEmail,
+	)
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("dolt: start managed sqlserver: %w", err)
+	}
+
+	return &serverProcess{cmd: cmd, socketPath: sc.Socket}, nil
+}
+
+// stopManagedServerGracePeriod bounds how long stopManagedServer waits for a
+// graceful shutdown (terminateProcess) before falling back to Process.Kill.
+const stopManagedServerGracePeriod = 5 * time.Second
+
+// stopManagedServer asks a managed server process, if any, to shut down
+// gracefully (terminateProcess, platform-specific — see procterm_unix.go /
+// procterm_windows.go), falling back to Process.Kill if it hasn't exited
+// within stopManagedServerGracePeriod, then waits for it to exit.
+func stopManagedServer(proc *serverProcess) {
+	if proc == nil || proc.cmd == nil || proc.cmd.Process == nil {
+		return
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- proc.cmd.Wait() }()
+
+	_ = terminateProcess(proc.cmd.Process)
+	select {
+	case <-done:
+		return
+	case <-time.After(stopManagedServerGracePeriod):
+	}
+
+	_ = proc.cmd.Process.Kill()
+	<-done
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
@@ -0,0 +1,140 @@
+package dolt
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestServerDSN(t *testing.T) {
+	cfg := &Config{
+		Database: "beads",
+		Server: ServerConfig{
+			Host:     "127.0.0.1",
+			Port:     3307,
+			User:     "root",
+			Password: "hunter2",
+		},
+	}
+
+	got, err := serverDSN(cfg)
+	if err != nil {
+		t.Fatalf("serverDSN: %v", err)
+	}
+	want := "root:hunter2@tcp(127.0.0.1:3307)/beads"
+	if got != want {
+		t.Fatalf("serverDSN mismatch: got %q want %q", got, want)
+	}
+}
+
+func TestServerDSN_Socket(t *testing.T) {
+	cfg := &Config{
+		Database: "beads",
+		Server: ServerConfig{
+			Socket: "/tmp/beads.sock",
+			User:   "root",
+		},
+	}
+
+	got, err := serverDSN(cfg)
+	if err != nil {
+		t.Fatalf("serverDSN: %v", err)
+	}
+	want := "root:@unix(/tmp/beads.sock)/beads"
+	if got != want {
+		t.Fatalf("serverDSN mismatch: got %q want %q", got, want)
+	}
+}
+
+func TestServerDSN_RequiresPortOrSocket(t *testing.T) {
+	cfg := &Config{Database: "beads"}
+	if _, err := serverDSN(cfg); err == nil {
+		t.Fatalf("expected error when neither Port nor Socket is set")
+	}
+}
+
+func TestServerDSN_RejectsPasswordWithDSNDelimiters(t *testing.T) {
+	cfg := &Config{
+		Database: "beads",
+		Server: ServerConfig{
+			Host:     "127.0.0.1",
+			Port:     3307,
+			User:     "root",
+			Password: "hunter@2",
+		},
+	}
+	if _, err := serverDSN(cfg); err == nil {
+		t.Fatalf("expected error for password containing a DSN delimiter")
+	}
+}
+
+// TestBuildServerConfigYAML_NestsUserCredentials guards against regressing
+// to a flat "user: <name>" / "password: <pw>" shape: dolt sqlserver's
+// config.yaml requires user to be a mapping of {name, password}, so a
+// top-level scalar either fails to unmarshal or is silently ignored,
+// leaving the managed server on its default credentials while the client
+// DSN authenticates as sc.User/sc.Password.
+func TestBuildServerConfigYAML_NestsUserCredentials(t *testing.T) {
+	cfg := &Config{
+		Server: ServerConfig{
+			Host:     "127.0.0.1",
+			Port:     3307,
+			User:     "root",
+			Password: "hunter2",
+			DataDir:  "/tmp/beads-dolt-data",
+		},
+	}
+
+	yamlCfg := buildServerConfigYAML(cfg)
+	out, err := yaml.Marshal(&yamlCfg)
+	if err != nil {
+		t.Fatalf("yaml.Marshal: %v", err)
+	}
+
+	got := string(out)
+	for _, want := range []string{"user:\n", "name: root", "password: hunter2", "data_dir: /tmp/beads-dolt-data", "host: 127.0.0.1", "port: 3307"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("generated config.yaml missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "\nuser: root") {
+		t.Fatalf("generated config.yaml should not have a flat top-level user scalar:\n%s", got)
+	}
+}
+
+// TestBuildServerConfigYAML_OmitsCommitter guards against reintroducing
+// committer identity as a config.yaml field: dolt sqlserver has no such
+// field, only an unrelated "user_session_vars" list, so committer identity
+// must stay out of this struct entirely (see startManagedServer's use of
+// DOLT_COMMITTER_NAME/DOLT_COMMITTER_EMAIL instead).
+func TestBuildServerConfigYAML_OmitsCommitter(t *testing.T) {
+	cfg := &Config{
+		CommitterName:  "Alice Example",
+		CommitterEmail: "alice+beads@example.com",
+		Server: ServerConfig{
+			Host:    "127.0.0.1",
+			Port:    3307,
+			User:    "root",
+			DataDir: "/tmp/beads-dolt-data",
+		},
+	}
+
+	yamlCfg := buildServerConfigYAML(cfg)
+	out, err := yaml.Marshal(&yamlCfg)
+	if err != nil {
+		t.Fatalf("yaml.Marshal: %v", err)
+	}
+
+	got := string(out)
+	for _, want := range []string{"name: root", "data_dir: /tmp/beads-dolt-data", "host: 127.0.0.1", "port: 3307"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("generated config.yaml missing %q:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"user_session_vars", "Alice Example", "alice+beads@example.com"} {
+		if strings.Contains(got, unwanted) {
+			t.Fatalf("generated config.yaml should not contain %q:\n%s", unwanted, got)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/coffeegoddd/beads/internal/storage"
+)
+
+var _ storage.Store = (*Store)(nil)
+
+func init() {
+	storage.Register("sqlite", openStore)
+}
+
+// openStore adapts New to the storage.Opener shape so the sqlite backend
+// can be selected by name via storage.Open instead of importing this
+// package directly.
+func openStore(ctx context.Context, options interface{}) (storage.Store, error) {
+	cfg, ok := options.(*Config)
+	if !ok {
+		return nil, fmt.Errorf("sqlite: storage.Open options must be *sqlite.Config, got %T", options)
+	}
+	return New(ctx, cfg)
+}
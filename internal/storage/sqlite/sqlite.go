@@ -0,0 +1,74 @@
+// Package sqlite is a minimal storage.Store backend for environments where
+// the Dolt binary/driver is unavailable (CI on unusual arches, skipIfNoDolt
+// cases) or branching/versioned history simply isn't needed.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	_ "modernc.org/sqlite" // pure Go, so this backend has no cgo/arch requirements
+)
+
+// Config describes how to open a sqlite-backed store.
+type Config struct {
+	// Path is the sqlite database file. ":memory:" is accepted for tests.
+	Path string
+}
+
+// Store is a minimal sqlite-backed storage.Store.
+type Store struct {
+	db *sql.DB
+}
+
+// New opens (creating if needed) a sqlite-backed Store at cfg.Path.
+func New(ctx context.Context, cfg *Config) (*Store, error) {
+	if cfg == nil || cfg.Path == "" {
+		return nil, fmt.Errorf("sqlite: Config.Path is required")
+	}
+	db, err := sql.Open("sqlite", cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: open %q: %w", cfg.Path, err)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("sqlite: ping %q: %w", cfg.Path, err)
+	}
+	const createTable = `CREATE TABLE IF NOT EXISTS beads_config (
+		config_key TEXT PRIMARY KEY,
+		config_value TEXT NOT NULL
+	)`
+	if _, err := db.ExecContext(ctx, createTable); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("sqlite: create beads_config table: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the underlying connection.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// SetConfig persists a single key/value pair in the store's config table.
+func (s *Store) SetConfig(ctx context.Context, key, value string) error {
+	_, err := s.db.ExecContext(ctx,
+		"INSERT INTO beads_config (config_key, config_value) VALUES (?, ?) "+
+			"ON CONFLICT(config_key) DO UPDATE SET config_value = excluded.config_value",
+		key, value)
+	if err != nil {
+		return fmt.Errorf("sqlite: set config %q: %w", key, err)
+	}
+	return nil
+}
+
+// GetConfig reads a single key from the store's config table.
+func (s *Store) GetConfig(ctx context.Context, key string) (string, error) {
+	var value string
+	err := s.db.QueryRowContext(ctx, "SELECT config_value FROM beads_config WHERE config_key = ?", key).Scan(&value)
+	if err != nil {
+		return "", fmt.Errorf("sqlite: get config %q: %w", key, err)
+	}
+	return value, nil
+}
@@ -0,0 +1,50 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSetGetConfig_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store, err := New(ctx, &Config{Path: ":memory:"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	if err := store.SetConfig(ctx, "greeting", "hello"); err != nil {
+		t.Fatalf("SetConfig: %v", err)
+	}
+	got, err := store.GetConfig(ctx, "greeting")
+	if err != nil {
+		t.Fatalf("GetConfig: %v", err)
+	}
+	if got != "hello" {
+		t.Fatalf("GetConfig mismatch: got %q want %q", got, "hello")
+	}
+
+	if err := store.SetConfig(ctx, "greeting", "updated"); err != nil {
+		t.Fatalf("SetConfig overwrite: %v", err)
+	}
+	got, err = store.GetConfig(ctx, "greeting")
+	if err != nil {
+		t.Fatalf("GetConfig after overwrite: %v", err)
+	}
+	if got != "updated" {
+		t.Fatalf("GetConfig after overwrite mismatch: got %q want %q", got, "updated")
+	}
+}
+
+func TestGetConfig_MissingKey(t *testing.T) {
+	ctx := context.Background()
+	store, err := New(ctx, &Config{Path: ":memory:"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	if _, err := store.GetConfig(ctx, "missing"); err == nil {
+		t.Fatalf("expected error for missing key")
+	}
+}
@@ -0,0 +1,58 @@
+// Package storage defines the backend-agnostic Store interface the rest of
+// beads programs against, plus a name -> Opener registry so callers can pick
+// a backend (Dolt, sqlite, ...) without this package importing any of them
+// directly — the same shape as database/sql driver registration, and the
+// multi-backend approach dex's storage/sql takes for postgres/sqlite/mysql.
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Store is the surface beads needs from a backend: config get/set plus
+// lifecycle. It's intentionally small — just the subset of *dolt.DoltStore
+// actually used outside the dolt package.
+type Store interface {
+	SetConfig(ctx context.Context, key, value string) error
+	GetConfig(ctx context.Context, key string) (string, error)
+	Close() error
+}
+
+// Opener constructs a Store from backend-specific options (e.g. *dolt.Config
+// or *sqlite.Config). Backends type-assert options themselves.
+type Opener func(ctx context.Context, options interface{}) (Store, error)
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]Opener{}
+)
+
+// Register makes a backend available under name. Backend packages call this
+// from an init(), mirroring database/sql drivers; callers pick the backend
+// at runtime by name via Open rather than importing it directly.
+func Register(name string, opener Opener) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if opener == nil {
+		panic("storage: Register opener is nil for backend " + name)
+	}
+	if _, dup := registry[name]; dup {
+		panic("storage: Register called twice for backend " + name)
+	}
+	registry[name] = opener
+}
+
+// Open dispatches to the backend registered under name. Callers must blank-
+// import the backend package (e.g. `_ "github.com/coffeegoddd/beads/internal/storage/dolt"`)
+// so its init() has run.
+func Open(ctx context.Context, name string, options interface{}) (Store, error) {
+	registryMu.Lock()
+	opener, ok := registry[name]
+	registryMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("storage: unknown backend %q (forgot a blank import?)", name)
+	}
+	return opener(ctx, options)
+}
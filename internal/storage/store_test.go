@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type fakeStore struct{ closed bool }
+
+func (f *fakeStore) SetConfig(ctx context.Context, key, value string) error { return nil }
+func (f *fakeStore) GetConfig(ctx context.Context, key string) (string, error) {
+	return "", errors.New("not found")
+}
+func (f *fakeStore) Close() error { f.closed = true; return nil }
+
+func withCleanRegistry(t *testing.T) {
+	t.Helper()
+	registryMu.Lock()
+	saved := registry
+	registry = map[string]Opener{}
+	registryMu.Unlock()
+	t.Cleanup(func() {
+		registryMu.Lock()
+		registry = saved
+		registryMu.Unlock()
+	})
+}
+
+func TestRegisterAndOpen(t *testing.T) {
+	withCleanRegistry(t)
+
+	var gotOptions interface{}
+	Register("fake", func(ctx context.Context, options interface{}) (Store, error) {
+		gotOptions = options
+		return &fakeStore{}, nil
+	})
+
+	store, err := Open(context.Background(), "fake", "some-options")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if gotOptions != "some-options" {
+		t.Fatalf("expected Opener to receive options verbatim, got %v", gotOptions)
+	}
+	if _, ok := store.(*fakeStore); !ok {
+		t.Fatalf("expected *fakeStore, got %T", store)
+	}
+}
+
+func TestOpen_UnknownBackend(t *testing.T) {
+	withCleanRegistry(t)
+
+	if _, err := Open(context.Background(), "does-not-exist", nil); err == nil {
+		t.Fatalf("expected error for unknown backend")
+	}
+}
+
+func TestRegister_PanicsOnDuplicate(t *testing.T) {
+	withCleanRegistry(t)
+
+	opener := func(ctx context.Context, options interface{}) (Store, error) { return &fakeStore{}, nil }
+	Register("dup", opener)
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected Register to panic on duplicate backend name")
+		}
+	}()
+	Register("dup", opener)
+}